@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// loadCheckpoint reads the set of checkpointKeys already recorded by a
+// previous run, for use with -resume.
+func loadCheckpoint(path string) (map[string]bool, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return map[string]bool{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open checkpoint file %s: %w", path, err)
+	}
+	defer f.Close()
+
+	completed := make(map[string]bool)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		completed[line] = true
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint file %s: %w", path, err)
+	}
+
+	return completed, nil
+}
+
+// checkpointWriter appends the checkpointKey of every successfully deleted
+// record to -checkpoint, fsyncing after each batch so a killed run can
+// resume with -resume without redeleting or reprocessing completed work.
+type checkpointWriter struct {
+	mu sync.Mutex
+	f  *os.File
+}
+
+func newCheckpointWriter(path string) (*checkpointWriter, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open checkpoint file %s: %w", path, err)
+	}
+
+	return &checkpointWriter{f: f}, nil
+}
+
+func (c *checkpointWriter) record(records []objectRecord) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, rec := range records {
+		if _, err := fmt.Fprintln(c.f, rec.checkpointKey()); err != nil {
+			return err
+		}
+	}
+
+	return c.f.Sync()
+}
+
+func (c *checkpointWriter) Close() error {
+	return c.f.Close()
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// failedKey pairs a record with the permanent error S3 returned for it.
+type failedKey struct {
+	record  objectRecord
+	code    string
+	message string
+}
+
+// MarshalJSON flattens failedKey into the record's fields plus the error,
+// so a -failed-keys file reads as one self-contained JSON object per line.
+func (f failedKey) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Key          string `json:"key"`
+		VersionId    string `json:"versionId,omitempty"`
+		Size         int64  `json:"size"`
+		LastModified string `json:"lastModified"`
+		Code         string `json:"code"`
+		Message      string `json:"message"`
+	}{
+		Key:          f.record.Key,
+		VersionId:    f.record.VersionId,
+		Size:         f.record.Size,
+		LastModified: f.record.LastModified.Format("2006-01-02T15:04:05Z07:00"),
+		Code:         f.code,
+		Message:      f.message,
+	})
+}
+
+// failedKeysWriter appends one JSON line per permanently-failed delete.
+type failedKeysWriter struct {
+	mu sync.Mutex
+	f  *os.File
+}
+
+func newFailedKeysWriter(path string) (*failedKeysWriter, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open failed-keys file %s: %w", path, err)
+	}
+
+	return &failedKeysWriter{f: f}, nil
+}
+
+func (w *failedKeysWriter) record(fk failedKey) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	enc := json.NewEncoder(w.f)
+	return enc.Encode(fk)
+}
+
+func (w *failedKeysWriter) Close() error {
+	return w.f.Close()
+}
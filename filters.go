@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// objectFilter holds the compiled set of client-side predicates applied to
+// each listed object in addition to the date cutoff. All predicates that are
+// configured (non-zero) must match for matches to return true; a zero-value
+// predicate is treated as "don't care" and is skipped.
+type objectFilter struct {
+	suffix       string
+	regex        *regexp.Regexp
+	minSize      int64
+	maxSize      int64
+	storageClass string
+	invert       bool
+}
+
+// newObjectFilter builds an objectFilter from the raw CLI flag values. An
+// empty regexPattern or storageClass leaves that predicate disabled.
+func newObjectFilter(suffix, regexPattern, storageClass string, minSize, maxSize int64, invert bool) (*objectFilter, error) {
+	f := &objectFilter{
+		suffix:       suffix,
+		minSize:      minSize,
+		maxSize:      maxSize,
+		storageClass: storageClass,
+		invert:       invert,
+	}
+
+	if regexPattern != "" {
+		re, err := regexp.Compile(regexPattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -regex pattern: %w", err)
+		}
+		f.regex = re
+	}
+
+	return f, nil
+}
+
+// matches reports whether obj satisfies every configured predicate (AND
+// semantics). It does not consider -invert; callers should use shouldDelete
+// to get the final deletion decision.
+func (f *objectFilter) matches(obj types.Object) bool {
+	return f.matchesAttrs(aws.ToString(obj.Key), aws.ToInt64(obj.Size), string(obj.StorageClass))
+}
+
+// matchesVersion is the types.ObjectVersion equivalent of matches, used when
+// listing with ListObjectVersions. Object and object-version storage
+// classes are distinct SDK types (types.ObjectStorageClass vs.
+// types.ObjectVersionStorageClass), so both are normalized to string before
+// reaching the shared matchesAttrs predicate.
+func (f *objectFilter) matchesVersion(v types.ObjectVersion) bool {
+	return f.matchesAttrs(aws.ToString(v.Key), aws.ToInt64(v.Size), string(v.StorageClass))
+}
+
+// matchesMarker is the delete-marker equivalent of matches. Delete markers
+// carry no size or storage class, so -min-size/-max-size/-storage-class are
+// ignored for them; only -suffix and -regex apply.
+func (f *objectFilter) matchesMarker(key string) bool {
+	if f.suffix != "" && !strings.HasSuffix(key, f.suffix) {
+		return false
+	}
+	if f.regex != nil && !f.regex.MatchString(key) {
+		return false
+	}
+
+	return true
+}
+
+func (f *objectFilter) matchesAttrs(key string, size int64, storageClass string) bool {
+	if f.suffix != "" && !strings.HasSuffix(key, f.suffix) {
+		return false
+	}
+	if f.regex != nil && !f.regex.MatchString(key) {
+		return false
+	}
+	if f.minSize > 0 && size < f.minSize {
+		return false
+	}
+	if f.maxSize > 0 && size > f.maxSize {
+		return false
+	}
+	if f.storageClass != "" && storageClass != f.storageClass {
+		return false
+	}
+
+	return true
+}
+
+// shouldDelete applies -invert on top of matches: normally a match means
+// "delete this object", but -invert flips that to "keep matches, delete
+// everything else".
+func (f *objectFilter) shouldDelete(obj types.Object) bool {
+	if f.invert {
+		return !f.matches(obj)
+	}
+	return f.matches(obj)
+}
+
+// shouldDeleteVersion is the ObjectVersion equivalent of shouldDelete.
+func (f *objectFilter) shouldDeleteVersion(v types.ObjectVersion) bool {
+	if f.invert {
+		return !f.matchesVersion(v)
+	}
+	return f.matchesVersion(v)
+}
+
+// shouldDeleteMarker is the delete-marker equivalent of shouldDelete.
+func (f *objectFilter) shouldDeleteMarker(key string) bool {
+	if f.invert {
+		return !f.matchesMarker(key)
+	}
+	return f.matchesMarker(key)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+func testObject(key string, size int64, storageClass types.ObjectStorageClass) types.Object {
+	return types.Object{
+		Key:          aws.String(key),
+		Size:         aws.Int64(size),
+		StorageClass: storageClass,
+	}
+}
+
+func TestObjectFilterMatchesANDSemantics(t *testing.T) {
+	f, err := newObjectFilter(".log", "", "", 100, 1000, false)
+	if err != nil {
+		t.Fatalf("newObjectFilter: %v", err)
+	}
+
+	cases := []struct {
+		name string
+		obj  types.Object
+		want bool
+	}{
+		{"matches suffix and size", testObject("app.log", 500, ""), true},
+		{"wrong suffix", testObject("app.txt", 500, ""), false},
+		{"too small", testObject("app.log", 10, ""), false},
+		{"too large", testObject("app.log", 5000, ""), false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := f.matches(c.obj); got != c.want {
+				t.Errorf("matches(%q) = %v, want %v", aws.ToString(c.obj.Key), got, c.want)
+			}
+		})
+	}
+}
+
+func TestObjectFilterStorageClass(t *testing.T) {
+	f, err := newObjectFilter("", "", "GLACIER", 0, 0, false)
+	if err != nil {
+		t.Fatalf("newObjectFilter: %v", err)
+	}
+
+	if !f.matches(testObject("a", 1, types.ObjectStorageClassGlacier)) {
+		t.Error("expected match for GLACIER object")
+	}
+	if f.matches(testObject("a", 1, types.ObjectStorageClassStandard)) {
+		t.Error("expected no match for STANDARD object")
+	}
+}
+
+func TestObjectFilterInvert(t *testing.T) {
+	f, err := newObjectFilter(".keep", "", "", 0, 0, true)
+	if err != nil {
+		t.Fatalf("newObjectFilter: %v", err)
+	}
+
+	if f.shouldDelete(testObject("a.keep", 1, "")) {
+		t.Error("invert should keep objects matching the filter")
+	}
+	if !f.shouldDelete(testObject("a.tmp", 1, "")) {
+		t.Error("invert should delete objects not matching the filter")
+	}
+}
+
+func TestObjectFilterInvalidRegex(t *testing.T) {
+	if _, err := newObjectFilter("", "(", "", 0, 0, false); err == nil {
+		t.Error("expected an error for an invalid -regex pattern")
+	}
+}
+
+func TestMatchesMarkerIgnoresSizeAndStorageClass(t *testing.T) {
+	f, err := newObjectFilter("", "", "GLACIER", 100, 0, false)
+	if err != nil {
+		t.Fatalf("newObjectFilter: %v", err)
+	}
+
+	if !f.matchesMarker("any-key") {
+		t.Error("delete markers have no size/storage class and should ignore those predicates")
+	}
+}
@@ -0,0 +1,20 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+)
+
+// progressLogger emits structured per-batch delete progress.
+var progressLogger = slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+func configureLogger(format string) {
+	var handler slog.Handler
+	switch format {
+	case "json":
+		handler = slog.NewJSONHandler(os.Stdout, nil)
+	default:
+		handler = slog.NewTextHandler(os.Stdout, nil)
+	}
+	progressLogger = slog.New(handler)
+}
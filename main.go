@@ -6,14 +6,14 @@ import (
 	"fmt"
 	"log"
 	"sync"
-	"sync/atomic"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
-	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"golang.org/x/time/rate"
 )
 
 func main() {
@@ -25,17 +25,97 @@ func main() {
 	accessKey := flag.String("access-key", "", "Access key for S3 or S3-compatible service")
 	secretKey := flag.String("secret-key", "", "Secret key for S3 or S3-compatible service")
 	maxKeysPerDelete := flag.Int("max-keys", 1000, "Maximum number of keys to delete in a single DeleteObjects call")
+	prefix := flag.String("prefix", "", "Only consider keys under this prefix (narrowed server-side)")
+	suffix := flag.String("suffix", "", "Only consider keys with this suffix")
+	regexPattern := flag.String("regex", "", "Only consider keys matching this regular expression")
+	minSize := flag.Int64("min-size", 0, "Only consider objects at least this many bytes")
+	maxSize := flag.Int64("max-size", 0, "Only consider objects at most this many bytes")
+	storageClass := flag.String("storage-class", "", "Only consider objects in this storage class")
+	invert := flag.Bool("invert", false, "Keep objects matching -suffix/-regex/-min-size/-max-size/-storage-class and delete the rest")
+	versions := flag.Bool("versions", false, "Operate on object versions and delete markers instead of current objects (requires a versioned bucket)")
+	versionMode := flag.String("version-mode", versionModeNoncurrentOnly, "Version cleanup mode when -versions is set: all-versions, noncurrent-only, or delete-markers-only")
+	dryRun := flag.Bool("dry-run", false, "List and filter objects but do not delete them; write a manifest instead")
+	manifestPath := flag.String("manifest", "", "Manifest output path for -dry-run (default: stdout)")
+	fromManifest := flag.String("from-manifest", "", "Skip listing and delete exactly the records in this manifest file")
+	checkpointPath := flag.String("checkpoint", "", "Checkpoint file recording successfully deleted batches, enabling -resume")
+	resume := flag.Bool("resume", false, "Resume a previous run, skipping records already recorded in -checkpoint")
+	rps := flag.Float64("rps", 0, "Maximum DeleteObjects requests per second across all workers (0 = unlimited)")
+	maxInflight := flag.Int("max-inflight", 0, "Maximum concurrent DeleteObjects requests across all workers (0 = unlimited)")
+	maxRetries := flag.Int("max-retries", 5, "Maximum retry attempts for transient per-key delete failures")
+	failedKeysPath := flag.String("failed-keys", "", "File to record keys that failed with a permanent error (e.g. AccessDenied, NoSuchKey)")
+	maxAuthFailures := flag.Int("max-auth-failures", 3, "Abort the run after this many consecutive authentication/authorization failures")
+	shardPrefixes := flag.String("shard-prefixes", "", "Shard listing across these prefixes: comma-separated list, @<file> (one prefix per line), or 'auto' to auto-discover via -delimiter")
+	delimiter := flag.String("delimiter", "/", "Delimiter used to auto-discover prefixes when -shard-prefixes=auto")
+	shardDepth := flag.Int("shard-depth", 1, "How many delimiter levels deep to recurse when auto-discovering prefixes")
+	shardConcurrency := flag.Int("shard-concurrency", 8, "Number of prefixes to list concurrently when -shard-prefixes is set")
+	metricsAddr := flag.String("metrics-addr", "", "Address to serve Prometheus metrics on (e.g. :9100); empty disables the metrics server")
+	logFormat := flag.String("log-format", "text", "Batch progress log format: text or json")
 	flag.Parse()
 
+	configureLogger(*logFormat)
+
 	if *bucketName == "" || *dateStr == "" {
 		log.Fatal("Bucket name and date are required")
 	}
 
+	if *metricsAddr != "" {
+		enableMetrics(*metricsAddr)
+	}
+
+	if *resume && *checkpointPath == "" {
+		log.Fatal("-resume requires -checkpoint")
+	}
+
 	targetDate, err := parseDate(*dateStr)
 	if err != nil {
 		log.Fatalf("Invalid date format: %v", err)
 	}
 
+	filter, err := newObjectFilter(*suffix, *regexPattern, *storageClass, *minSize, *maxSize, *invert)
+	if err != nil {
+		log.Fatalf("Invalid filter flags: %v", err)
+	}
+
+	var completed map[string]bool
+	if *resume {
+		completed, err = loadCheckpoint(*checkpointPath)
+		if err != nil {
+			log.Fatalf("Failed to load checkpoint: %v", err)
+		}
+	}
+
+	var checkpoint *checkpointWriter
+	if *checkpointPath != "" {
+		checkpoint, err = newCheckpointWriter(*checkpointPath)
+		if err != nil {
+			log.Fatalf("Failed to open checkpoint file: %v", err)
+		}
+		defer checkpoint.Close()
+	}
+
+	var failedKeys *failedKeysWriter
+	if *failedKeysPath != "" {
+		failedKeys, err = newFailedKeysWriter(*failedKeysPath)
+		if err != nil {
+			log.Fatalf("Failed to open failed-keys file: %v", err)
+		}
+		defer failedKeys.Close()
+	}
+
+	var limiter *rate.Limiter
+	if *rps > 0 {
+		burst := int(*rps)
+		if burst < 1 {
+			burst = 1
+		}
+		limiter = rate.NewLimiter(rate.Limit(*rps), burst)
+	}
+
+	var inflight chan struct{}
+	if *maxInflight > 0 {
+		inflight = make(chan struct{}, *maxInflight)
+	}
+
 	var client *s3.Client
 	if *endpoint != "" {
 		// S3-compatible service
@@ -51,18 +131,78 @@ func main() {
 		log.Fatalf("Failed to create S3 client: %v", err)
 	}
 
-	objectsToDelete := make(chan []types.ObjectIdentifier, *workers)
+	var shardedPrefixes []string
+	if *shardPrefixes == "auto" {
+		shardedPrefixes, err = discoverPrefixesUnder(client, *bucketName, *delimiter, *prefix, *shardDepth)
+	} else {
+		shardedPrefixes, err = parsePrefixList(*shardPrefixes)
+		if err == nil && *prefix != "" {
+			for i, p := range shardedPrefixes {
+				shardedPrefixes[i] = *prefix + p
+			}
+		}
+	}
+	if err != nil {
+		log.Fatalf("Failed to resolve -shard-prefixes: %v", err)
+	}
+
+	objectsToDelete := make(chan []objectRecord, *workers)
 	var wg sync.WaitGroup
 	var totalDeleted int64
 
-	// Start worker goroutines
-	for i := 0; i < *workers; i++ {
+	if *dryRun {
+		manifestOut, closeManifest, err := openManifestOutput(*manifestPath)
+		if err != nil {
+			log.Fatalf("Failed to open manifest output: %v", err)
+		}
+		defer closeManifest()
+
 		wg.Add(1)
-		go worker(client, *bucketName, objectsToDelete, &wg, &totalDeleted)
+		go manifestSink(objectsToDelete, manifestOut, &wg, &totalDeleted)
+	} else {
+		cfg := &workerConfig{
+			client:                  client,
+			bucket:                  *bucketName,
+			checkpoint:              checkpoint,
+			failedKeys:              failedKeys,
+			limiter:                 limiter,
+			inflight:                inflight,
+			maxRetries:              *maxRetries,
+			maxAuthFailures:         int32(*maxAuthFailures),
+			consecutiveAuthFailures: new(int32),
+			batchSeq:                new(int64),
+		}
+
+		for i := 0; i < *workers; i++ {
+			wg.Add(1)
+			go worker(cfg, objectsToDelete, &wg, &totalDeleted)
+		}
 	}
 
 	startTime := time.Now()
-	totalObjects, err := listAndDeleteObjects(client, *bucketName, targetDate, objectsToDelete, *maxKeysPerDelete)
+	var totalObjects int
+	switch {
+	case *fromManifest != "":
+		entries, ferr := loadManifest(*fromManifest)
+		if ferr != nil {
+			log.Fatalf("Failed to load manifest: %v", ferr)
+		}
+		totalObjects = enqueueRecords(entries, completed, objectsToDelete, *maxKeysPerDelete)
+	case len(shardedPrefixes) > 0:
+		var shards []shardProgress
+		totalObjects, shards, err = listAndDeleteObjectsSharded(client, *bucketName, shardedPrefixes, targetDate, filter, completed, objectsToDelete, *maxKeysPerDelete, *shardConcurrency)
+		for _, s := range shards {
+			if s.err != nil {
+				log.Printf("Shard %q failed after listing %d objects: %v", s.prefix, s.objects, s.err)
+			} else {
+				log.Printf("Shard %q: listed %d objects", s.prefix, s.objects)
+			}
+		}
+	case *versions:
+		totalObjects, err = listAndDeleteObjectVersions(client, *bucketName, *prefix, targetDate, filter, *versionMode, completed, objectsToDelete, *maxKeysPerDelete)
+	default:
+		totalObjects, err = listAndDeleteObjects(client, *bucketName, *prefix, targetDate, filter, completed, objectsToDelete, *maxKeysPerDelete)
+	}
 	if err != nil {
 		log.Fatalf("Failed to list and delete objects: %v", err)
 	}
@@ -71,7 +211,11 @@ func main() {
 	wg.Wait()
 
 	duration := time.Since(startTime)
-	log.Printf("Operation complete. Deleted %d/%d objects in %v", totalDeleted, totalObjects, duration)
+	if *dryRun {
+		log.Printf("Dry run complete. Wrote %d/%d objects to manifest in %v", totalDeleted, totalObjects, duration)
+	} else {
+		log.Printf("Operation complete. Deleted %d/%d objects in %v", totalDeleted, totalObjects, duration)
+	}
 }
 
 func createS3CompatibleClient(endpoint, region, accessKey, secretKey string) (*s3.Client, error) {
@@ -100,33 +244,16 @@ func createAWSS3Client(region string) (*s3.Client, error) {
 	return s3.NewFromConfig(cfg), nil
 }
 
-func worker(client *s3.Client, bucket string, objectsToDelete <-chan []types.ObjectIdentifier, wg *sync.WaitGroup, totalDeleted *int64) {
-	defer wg.Done()
-
-	for objects := range objectsToDelete {
-		_, err := client.DeleteObjects(context.TODO(), &s3.DeleteObjectsInput{
-			Bucket: aws.String(bucket),
-			Delete: &types.Delete{
-				Objects: objects,
-				Quiet:   aws.Bool(true),
-			},
-		})
-
-		if err != nil {
-			log.Printf("Failed to delete objects: %v", err)
-		} else {
-			atomic.AddInt64(totalDeleted, int64(len(objects)))
-			fmt.Printf("Deleted %d objects\n", len(objects))
-		}
-	}
-}
-
-func listAndDeleteObjects(client *s3.Client, bucket string, targetDate time.Time, objectsToDelete chan<- []types.ObjectIdentifier, maxKeysPerDelete int) (int, error) {
-	paginator := s3.NewListObjectsV2Paginator(client, &s3.ListObjectsV2Input{
+func listAndDeleteObjects(client *s3.Client, bucket, prefix string, targetDate time.Time, filter *objectFilter, completed map[string]bool, objectsToDelete chan<- []objectRecord, maxKeysPerDelete int) (int, error) {
+	input := &s3.ListObjectsV2Input{
 		Bucket: aws.String(bucket),
-	})
+	}
+	if prefix != "" {
+		input.Prefix = aws.String(prefix)
+	}
+	paginator := s3.NewListObjectsV2Paginator(client, input)
 
-	var objectsBuffer []types.ObjectIdentifier
+	var recordsBuffer []objectRecord
 	totalObjects := 0
 
 	for paginator.HasMorePages() {
@@ -136,20 +263,32 @@ func listAndDeleteObjects(client *s3.Client, bucket string, targetDate time.Time
 		}
 
 		for _, obj := range page.Contents {
-			if obj.LastModified.Before(targetDate) {
-				objectsBuffer = append(objectsBuffer, types.ObjectIdentifier{Key: obj.Key})
-				totalObjects++
-
-				if len(objectsBuffer) >= maxKeysPerDelete {
-					objectsToDelete <- objectsBuffer
-					objectsBuffer = []types.ObjectIdentifier{}
-				}
+			if !obj.LastModified.Before(targetDate) || !filter.shouldDelete(obj) {
+				continue
+			}
+
+			rec := objectRecord{
+				Key:          aws.ToString(obj.Key),
+				Size:         aws.ToInt64(obj.Size),
+				LastModified: *obj.LastModified,
+			}
+			if completed != nil && completed[rec.checkpointKey()] {
+				continue
+			}
+
+			recordsBuffer = append(recordsBuffer, rec)
+			totalObjects++
+			recordListed(1)
+
+			if len(recordsBuffer) >= maxKeysPerDelete {
+				objectsToDelete <- recordsBuffer
+				recordsBuffer = []objectRecord{}
 			}
 		}
 	}
 
-	if len(objectsBuffer) > 0 {
-		objectsToDelete <- objectsBuffer
+	if len(recordsBuffer) > 0 {
+		objectsToDelete <- recordsBuffer
 	}
 
 	return totalObjects, nil
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// openManifestOutput returns the writer -dry-run should write its manifest
+// to: the file at path, or stdout when path is empty. The returned close
+// func is always safe to call and defer.
+func openManifestOutput(path string) (io.Writer, func(), error) {
+	if path == "" {
+		return os.Stdout, func() {}, nil
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create manifest file %s: %w", path, err)
+	}
+
+	return f, func() { f.Close() }, nil
+}
+
+// manifestSink drains records and writes one newline-delimited JSON manifest
+// entry per record, in place of actually deleting anything. It is the
+// -dry-run counterpart to worker.
+func manifestSink(records <-chan []objectRecord, w io.Writer, wg *sync.WaitGroup, totalWritten *int64) {
+	defer wg.Done()
+
+	enc := json.NewEncoder(w)
+
+	for batch := range records {
+		for _, rec := range batch {
+			if err := enc.Encode(rec); err != nil {
+				log.Printf("Failed to write manifest entry for %s: %v", rec.Key, err)
+				continue
+			}
+			atomic.AddInt64(totalWritten, 1)
+		}
+	}
+}
+
+// loadManifest reads a newline-delimited JSON manifest previously produced
+// by -dry-run, for use with -from-manifest.
+func loadManifest(path string) ([]objectRecord, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open manifest file %s: %w", path, err)
+	}
+	defer f.Close()
+
+	var entries []objectRecord
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var rec objectRecord
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return nil, fmt.Errorf("failed to parse manifest entry: %w", err)
+		}
+		entries = append(entries, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read manifest file %s: %w", path, err)
+	}
+
+	return entries, nil
+}
+
+// enqueueRecords feeds manifest entries into objectsToDelete in batches,
+// skipping any already present in completed (set when resuming from a
+// checkpoint). It returns the number of records enqueued.
+func enqueueRecords(entries []objectRecord, completed map[string]bool, objectsToDelete chan<- []objectRecord, maxKeysPerDelete int) int {
+	var buffer []objectRecord
+	total := 0
+
+	for _, rec := range entries {
+		if completed != nil && completed[rec.checkpointKey()] {
+			continue
+		}
+
+		buffer = append(buffer, rec)
+		total++
+		recordListed(1)
+
+		if len(buffer) >= maxKeysPerDelete {
+			objectsToDelete <- buffer
+			buffer = []objectRecord{}
+		}
+	}
+
+	if len(buffer) > 0 {
+		objectsToDelete <- buffer
+	}
+
+	return total
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// metrics holds the Prometheus collectors exposed on -metrics-addr.
+type metrics struct {
+	objectsListed  prometheus.Counter
+	objectsDeleted prometheus.Counter
+	deleteErrors   *prometheus.CounterVec
+	batchDuration  prometheus.Histogram
+	bytesReclaimed prometheus.Counter
+}
+
+func newMetrics() *metrics {
+	m := &metrics{
+		objectsListed: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "s3remover_objects_listed_total",
+			Help: "Total number of objects listed for possible deletion.",
+		}),
+		objectsDeleted: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "s3remover_objects_deleted_total",
+			Help: "Total number of objects successfully deleted.",
+		}),
+		deleteErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "s3remover_delete_errors_total",
+			Help: "Total number of delete errors, per key or per failed DeleteObjects call, by S3 error code.",
+		}, []string{"code"}),
+		batchDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "s3remover_delete_batch_duration_seconds",
+			Help:    "Duration of individual DeleteObjects calls.",
+			Buckets: prometheus.DefBuckets,
+		}),
+		bytesReclaimed: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "s3remover_bytes_reclaimed_total",
+			Help: "Total size in bytes of successfully deleted objects.",
+		}),
+	}
+
+	prometheus.MustRegister(m.objectsListed, m.objectsDeleted, m.deleteErrors, m.batchDuration, m.bytesReclaimed)
+	return m
+}
+
+// activeMetrics is nil unless -metrics-addr was set.
+var activeMetrics *metrics
+
+func enableMetrics(addr string) {
+	activeMetrics = newMetrics()
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+
+	go func() {
+		if err := http.ListenAndServe(addr, mux); err != nil {
+			log.Printf("Metrics server stopped: %v", err)
+		}
+	}()
+}
+
+func recordListed(n int) {
+	if activeMetrics != nil {
+		activeMetrics.objectsListed.Add(float64(n))
+	}
+}
+
+func recordDeleted(n int, bytes int64) {
+	if activeMetrics != nil {
+		activeMetrics.objectsDeleted.Add(float64(n))
+		activeMetrics.bytesReclaimed.Add(float64(bytes))
+	}
+}
+
+func recordDeleteError(code string) {
+	if activeMetrics != nil {
+		activeMetrics.deleteErrors.WithLabelValues(code).Inc()
+	}
+}
+
+func observeBatchDuration(seconds float64) {
+	if activeMetrics != nil {
+		activeMetrics.batchDuration.Observe(seconds)
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// objectRecord describes a single S3 object (or object version / delete
+// marker) selected for deletion. It carries enough information to both
+// build a DeleteObjects request and serialize a -dry-run manifest entry.
+type objectRecord struct {
+	Key          string    `json:"key"`
+	VersionId    string    `json:"versionId,omitempty"`
+	Size         int64     `json:"size"`
+	LastModified time.Time `json:"lastModified"`
+}
+
+// toObjectIdentifier converts a record into the type DeleteObjects expects.
+func (r objectRecord) toObjectIdentifier() types.ObjectIdentifier {
+	id := types.ObjectIdentifier{Key: aws.String(r.Key)}
+	if r.VersionId != "" {
+		id.VersionId = aws.String(r.VersionId)
+	}
+	return id
+}
+
+// checkpointKey identifies a record for checkpoint/resume purposes. Two
+// records with the same key but different version IDs are distinct.
+func (r objectRecord) checkpointKey() string {
+	return r.Key + "\x00" + r.VersionId
+}
@@ -0,0 +1,31 @@
+package main
+
+import "testing"
+
+func TestObjectRecordCheckpointKeyDistinguishesVersions(t *testing.T) {
+	a := objectRecord{Key: "a.txt", VersionId: "v1"}
+	b := objectRecord{Key: "a.txt", VersionId: "v2"}
+	c := objectRecord{Key: "a.txt"}
+
+	if a.checkpointKey() == b.checkpointKey() {
+		t.Error("records with different version IDs must have different checkpoint keys")
+	}
+	if a.checkpointKey() == c.checkpointKey() {
+		t.Error("a versioned and unversioned record for the same key must differ")
+	}
+	if a.checkpointKey() != (objectRecord{Key: "a.txt", VersionId: "v1"}).checkpointKey() {
+		t.Error("checkpointKey must be stable for identical records")
+	}
+}
+
+func TestObjectRecordToObjectIdentifier(t *testing.T) {
+	withVersion := objectRecord{Key: "a.txt", VersionId: "v1"}.toObjectIdentifier()
+	if withVersion.VersionId == nil || *withVersion.VersionId != "v1" {
+		t.Error("expected VersionId to be set when the record has one")
+	}
+
+	withoutVersion := objectRecord{Key: "a.txt"}.toObjectIdentifier()
+	if withoutVersion.VersionId != nil {
+		t.Error("expected VersionId to be nil when the record has none")
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/aws/smithy-go"
+)
+
+// transientErrorCodes are S3 error codes worth retrying: throttling and
+// transient server-side failures. Anything else (AccessDenied, NoSuchKey,
+// etc.) is treated as permanent.
+var transientErrorCodes = map[string]bool{
+	"SlowDown":           true,
+	"InternalError":      true,
+	"ServiceUnavailable": true,
+	"RequestTimeout":     true,
+	"503":                true,
+}
+
+// authErrorCodes are codes indicating the credentials in use are invalid or
+// insufficiently privileged, as opposed to a transient or per-key problem.
+var authErrorCodes = map[string]bool{
+	"AccessDenied":          true,
+	"InvalidAccessKeyId":    true,
+	"SignatureDoesNotMatch": true,
+	"ExpiredToken":          true,
+}
+
+func isTransientCode(code string) bool {
+	return transientErrorCodes[code]
+}
+
+func isAuthError(code string) bool {
+	return authErrorCodes[code]
+}
+
+// errorCode extracts the S3/smithy error code from an error returned by the
+// SDK, or "" if it isn't an API error (e.g. a network failure).
+func errorCode(err error) string {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.ErrorCode()
+	}
+	return ""
+}
+
+const (
+	baseBackoff = 200 * time.Millisecond
+	maxBackoff  = 30 * time.Second
+)
+
+// sleepBackoff blocks for an exponentially increasing duration (with full
+// jitter) based on the zero-indexed retry attempt number.
+func sleepBackoff(attempt int) {
+	time.Sleep(backoffDuration(attempt))
+}
+
+// maxBackoffAttempt bounds the exponent passed to math.Pow: beyond this,
+// baseBackoff*2^attempt already dwarfs maxBackoff, so clamping attempt here
+// keeps the float64->time.Duration conversion from overflowing int64
+// nanoseconds (and wrapping negative) for large attempt counts.
+const maxBackoffAttempt = 32
+
+func backoffDuration(attempt int) time.Duration {
+	if attempt > maxBackoffAttempt {
+		attempt = maxBackoffAttempt
+	}
+	d := time.Duration(float64(baseBackoff) * math.Pow(2, float64(attempt)))
+	if d > maxBackoff || d < 0 {
+		d = maxBackoff
+	}
+	return time.Duration(rand.Int63n(int64(d) + 1))
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIsTransientCode(t *testing.T) {
+	transient := []string{"SlowDown", "InternalError", "ServiceUnavailable", "RequestTimeout", "503"}
+	for _, code := range transient {
+		if !isTransientCode(code) {
+			t.Errorf("expected %q to be transient", code)
+		}
+	}
+
+	permanent := []string{"AccessDenied", "NoSuchKey", ""}
+	for _, code := range permanent {
+		if isTransientCode(code) {
+			t.Errorf("expected %q to not be transient", code)
+		}
+	}
+}
+
+func TestIsAuthError(t *testing.T) {
+	auth := []string{"AccessDenied", "InvalidAccessKeyId", "SignatureDoesNotMatch", "ExpiredToken"}
+	for _, code := range auth {
+		if !isAuthError(code) {
+			t.Errorf("expected %q to be an auth error", code)
+		}
+	}
+
+	if isAuthError("SlowDown") {
+		t.Error("SlowDown is transient, not an auth error")
+	}
+}
+
+func TestBackoffDurationGrowsAndCaps(t *testing.T) {
+	for attempt := 0; attempt < 10; attempt++ {
+		d := backoffDuration(attempt)
+		if d < 0 {
+			t.Fatalf("backoffDuration(%d) returned a negative duration: %v", attempt, d)
+		}
+		if d > maxBackoff {
+			t.Fatalf("backoffDuration(%d) = %v, exceeds maxBackoff %v", attempt, d, maxBackoff)
+		}
+		bound := baseBackoff << attempt
+		if bound > maxBackoff {
+			bound = maxBackoff
+		}
+		if d > bound {
+			t.Fatalf("backoffDuration(%d) = %v, exceeds base*2^attempt bound %v", attempt, d, bound)
+		}
+	}
+
+	// A late attempt should be capable of producing durations close to the
+	// cap, since jitter is bounded by min(base*2^attempt, maxBackoff).
+	const lateAttempt = 20
+	var largest time.Duration
+	for i := 0; i < 50; i++ {
+		d := backoffDuration(lateAttempt)
+		if d > maxBackoff {
+			t.Fatalf("backoffDuration(%d) exceeded maxBackoff", lateAttempt)
+		}
+		if d > largest {
+			largest = d
+		}
+	}
+	if largest < maxBackoff/2 {
+		t.Errorf("backoffDuration(%d) never exceeded maxBackoff/2 in 50 draws; largest %v", lateAttempt, largest)
+	}
+}
+
+// TestBackoffDurationHugeAttemptDoesNotOverflow exercises attempt counts far
+// beyond where base*2^attempt would overflow a time.Duration (int64 ns) if
+// computed directly, e.g. sustained SlowDown retries against an unbounded
+// -max-retries. It must still return a valid, non-negative duration capped
+// at maxBackoff rather than panicking in rand.Int63n.
+func TestBackoffDurationHugeAttemptDoesNotOverflow(t *testing.T) {
+	for _, attempt := range []int{62, 100, 1000, 1 << 20} {
+		for i := 0; i < 20; i++ {
+			d := backoffDuration(attempt)
+			if d < 0 {
+				t.Fatalf("backoffDuration(%d) returned a negative duration: %v", attempt, d)
+			}
+			if d > maxBackoff {
+				t.Fatalf("backoffDuration(%d) = %v, exceeds maxBackoff %v", attempt, d, maxBackoff)
+			}
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// shardProgress reports how many objects a single -shard-prefixes shard
+// processed, for the per-shard summary printed at the end of a run.
+type shardProgress struct {
+	prefix  string
+	objects int
+	err     error
+}
+
+// parsePrefixList turns a -shard-prefixes value into a concrete prefix
+// list: "@path" reads one prefix per line from a file, anything else is
+// treated as a comma-separated list.
+func parsePrefixList(value string) ([]string, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	if path, ok := strings.CutPrefix(value, "@"); ok {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open prefix list file %s: %w", path, err)
+		}
+		defer f.Close()
+
+		var prefixes []string
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			p := strings.TrimSpace(scanner.Text())
+			if p != "" {
+				prefixes = append(prefixes, p)
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read prefix list file %s: %w", path, err)
+		}
+		return prefixes, nil
+	}
+
+	var prefixes []string
+	for _, p := range strings.Split(value, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			prefixes = append(prefixes, p)
+		}
+	}
+	return prefixes, nil
+}
+
+// discoverPrefixesUnder auto-discovers a prefix list by walking
+// CommonPrefixes under delimiter starting at prefix, descending up to depth
+// levels. depth 1 returns just the top-level prefixes under prefix.
+func discoverPrefixesUnder(client *s3.Client, bucket, delimiter, prefix string, depth int) ([]string, error) {
+	if depth <= 0 {
+		return []string{prefix}, nil
+	}
+
+	input := &s3.ListObjectsV2Input{
+		Bucket:    aws.String(bucket),
+		Delimiter: aws.String(delimiter),
+	}
+	if prefix != "" {
+		input.Prefix = aws.String(prefix)
+	}
+	paginator := s3.NewListObjectsV2Paginator(client, input)
+
+	var common []string
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return nil, err
+		}
+		for _, cp := range page.CommonPrefixes {
+			common = append(common, aws.ToString(cp.Prefix))
+		}
+	}
+
+	if len(common) == 0 {
+		return []string{prefix}, nil
+	}
+	if depth == 1 {
+		return common, nil
+	}
+
+	var all []string
+	for _, p := range common {
+		sub, err := discoverPrefixesUnder(client, bucket, delimiter, p, depth-1)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, sub...)
+	}
+	return all, nil
+}
+
+// listAndDeleteObjectsSharded fans out listAndDeleteObjects across
+// prefixes, running up to shardConcurrency of them at once, all feeding the
+// same objectsToDelete channel. It returns the total object count across
+// shards plus per-shard progress for the final summary.
+func listAndDeleteObjectsSharded(client *s3.Client, bucket string, prefixes []string, targetDate time.Time, filter *objectFilter, completed map[string]bool, objectsToDelete chan<- []objectRecord, maxKeysPerDelete, shardConcurrency int) (int, []shardProgress, error) {
+	results := make([]shardProgress, len(prefixes))
+	sem := make(chan struct{}, shardConcurrency)
+	var wg sync.WaitGroup
+
+	for i, prefix := range prefixes {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(i int, prefix string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			n, err := listAndDeleteObjects(client, bucket, prefix, targetDate, filter, completed, objectsToDelete, maxKeysPerDelete)
+			results[i] = shardProgress{prefix: prefix, objects: n, err: err}
+		}(i, prefix)
+	}
+
+	wg.Wait()
+
+	total := 0
+	var firstErr error
+	for _, r := range results {
+		total += r.objects
+		if r.err != nil && firstErr == nil {
+			firstErr = r.err
+		}
+	}
+
+	return total, results, firstErr
+}
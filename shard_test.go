@@ -0,0 +1,55 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParsePrefixListCommaSeparated(t *testing.T) {
+	got, err := parsePrefixList("00,01, 02 ,,ff")
+	if err != nil {
+		t.Fatalf("parsePrefixList: %v", err)
+	}
+
+	want := []string{"00", "01", "02", "ff"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parsePrefixList = %v, want %v", got, want)
+	}
+}
+
+func TestParsePrefixListEmpty(t *testing.T) {
+	got, err := parsePrefixList("")
+	if err != nil {
+		t.Fatalf("parsePrefixList: %v", err)
+	}
+	if got != nil {
+		t.Errorf("parsePrefixList(\"\") = %v, want nil", got)
+	}
+}
+
+func TestParsePrefixListFromFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "prefixes.txt")
+	content := "images/\nvideos/\n\nlogs/\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	got, err := parsePrefixList("@" + path)
+	if err != nil {
+		t.Fatalf("parsePrefixList: %v", err)
+	}
+
+	want := []string{"images/", "videos/", "logs/"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parsePrefixList = %v, want %v", got, want)
+	}
+}
+
+func TestParsePrefixListMissingFile(t *testing.T) {
+	if _, err := parsePrefixList("@/no/such/file"); err == nil {
+		t.Error("expected an error for a missing prefix list file")
+	}
+}
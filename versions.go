@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// Version modes supported by -version-mode when -versions is set.
+const (
+	versionModeAll               = "all-versions"
+	versionModeNoncurrentOnly    = "noncurrent-only"
+	versionModeDeleteMarkersOnly = "delete-markers-only"
+)
+
+func validVersionMode(mode string) bool {
+	switch mode {
+	case versionModeAll, versionModeNoncurrentOnly, versionModeDeleteMarkersOnly:
+		return true
+	default:
+		return false
+	}
+}
+
+// includeByMode reports whether an entry with the given IsLatest value
+// should be considered for deletion under mode. It applies only to the
+// versionModeNoncurrentOnly distinction; versionModeAll and
+// versionModeDeleteMarkersOnly never exclude based on IsLatest here (the
+// caller is responsible for skipping the Versions loop entirely in
+// versionModeDeleteMarkersOnly).
+func includeByMode(mode string, isLatest bool) bool {
+	return mode != versionModeNoncurrentOnly || !isLatest
+}
+
+// listAndDeleteObjectVersions is the ListObjectVersions counterpart of
+// listAndDeleteObjects. It walks every version and delete marker in the
+// bucket (or under prefix) and enqueues the ones selected by mode, filter,
+// and targetDate for deletion. Every enqueued record carries a VersionId,
+// since DeleteObjects without one only creates another delete marker rather
+// than removing data. completed, if non-nil, holds checkpointKeys already
+// processed by a prior run and is used to skip them on -resume.
+func listAndDeleteObjectVersions(client *s3.Client, bucket, prefix string, targetDate time.Time, filter *objectFilter, mode string, completed map[string]bool, objectsToDelete chan<- []objectRecord, maxKeysPerDelete int) (int, error) {
+	if !validVersionMode(mode) {
+		return 0, fmt.Errorf("invalid -version-mode %q", mode)
+	}
+
+	input := &s3.ListObjectVersionsInput{
+		Bucket: aws.String(bucket),
+	}
+	if prefix != "" {
+		input.Prefix = aws.String(prefix)
+	}
+	paginator := s3.NewListObjectVersionsPaginator(client, input)
+
+	var recordsBuffer []objectRecord
+	totalObjects := 0
+
+	enqueue := func(rec objectRecord) {
+		if completed != nil && completed[rec.checkpointKey()] {
+			return
+		}
+
+		recordsBuffer = append(recordsBuffer, rec)
+		totalObjects++
+		recordListed(1)
+
+		if len(recordsBuffer) >= maxKeysPerDelete {
+			objectsToDelete <- recordsBuffer
+			recordsBuffer = []objectRecord{}
+		}
+	}
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return totalObjects, err
+		}
+
+		if mode != versionModeDeleteMarkersOnly {
+			for _, v := range page.Versions {
+				if !includeByMode(mode, aws.ToBool(v.IsLatest)) {
+					continue
+				}
+				if v.LastModified.Before(targetDate) && filter.shouldDeleteVersion(v) {
+					enqueue(objectRecord{
+						Key:          aws.ToString(v.Key),
+						VersionId:    aws.ToString(v.VersionId),
+						Size:         aws.ToInt64(v.Size),
+						LastModified: *v.LastModified,
+					})
+				}
+			}
+		}
+
+		for _, m := range page.DeleteMarkers {
+			if !includeByMode(mode, aws.ToBool(m.IsLatest)) {
+				continue
+			}
+			key := aws.ToString(m.Key)
+			if m.LastModified.Before(targetDate) && filter.shouldDeleteMarker(key) {
+				enqueue(objectRecord{
+					Key:          key,
+					VersionId:    aws.ToString(m.VersionId),
+					LastModified: *m.LastModified,
+				})
+			}
+		}
+	}
+
+	if len(recordsBuffer) > 0 {
+		objectsToDelete <- recordsBuffer
+	}
+
+	return totalObjects, nil
+}
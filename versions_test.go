@@ -0,0 +1,94 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+func TestValidVersionMode(t *testing.T) {
+	valid := []string{versionModeAll, versionModeNoncurrentOnly, versionModeDeleteMarkersOnly}
+	for _, m := range valid {
+		if !validVersionMode(m) {
+			t.Errorf("expected %q to be a valid -version-mode", m)
+		}
+	}
+
+	if validVersionMode("bogus") {
+		t.Error("expected an unknown -version-mode to be invalid")
+	}
+}
+
+func TestIncludeByMode(t *testing.T) {
+	cases := []struct {
+		mode     string
+		isLatest bool
+		want     bool
+	}{
+		{versionModeAll, true, true},
+		{versionModeAll, false, true},
+		{versionModeNoncurrentOnly, true, false},
+		{versionModeNoncurrentOnly, false, true},
+		{versionModeDeleteMarkersOnly, true, true},
+		{versionModeDeleteMarkersOnly, false, true},
+	}
+	for _, c := range cases {
+		if got := includeByMode(c.mode, c.isLatest); got != c.want {
+			t.Errorf("includeByMode(%q, %v) = %v, want %v", c.mode, c.isLatest, got, c.want)
+		}
+	}
+}
+
+func testObjectVersion(key string, size int64, storageClass types.ObjectVersionStorageClass) types.ObjectVersion {
+	return types.ObjectVersion{
+		Key:          aws.String(key),
+		Size:         aws.Int64(size),
+		StorageClass: storageClass,
+	}
+}
+
+func TestObjectFilterMatchesVersion(t *testing.T) {
+	f, err := newObjectFilter(".log", "", "GLACIER", 100, 1000, false)
+	if err != nil {
+		t.Fatalf("newObjectFilter: %v", err)
+	}
+
+	if !f.matchesVersion(testObjectVersion("app.log", 500, types.ObjectVersionStorageClassGlacier)) {
+		t.Error("expected a matching version to satisfy the filter")
+	}
+	if f.matchesVersion(testObjectVersion("app.log", 500, types.ObjectVersionStorageClassStandard)) {
+		t.Error("expected a wrong-storage-class version to not match")
+	}
+	if f.matchesVersion(testObjectVersion("app.txt", 500, types.ObjectVersionStorageClassGlacier)) {
+		t.Error("expected a wrong-suffix version to not match")
+	}
+}
+
+func TestObjectFilterShouldDeleteVersionInvert(t *testing.T) {
+	f, err := newObjectFilter(".keep", "", "", 0, 0, true)
+	if err != nil {
+		t.Fatalf("newObjectFilter: %v", err)
+	}
+
+	if f.shouldDeleteVersion(testObjectVersion("a.keep", 1, "")) {
+		t.Error("invert should keep versions matching the filter")
+	}
+	if !f.shouldDeleteVersion(testObjectVersion("a.tmp", 1, "")) {
+		t.Error("invert should delete versions not matching the filter")
+	}
+}
+
+func TestObjectFilterShouldDeleteMarker(t *testing.T) {
+	f, err := newObjectFilter(".log", "", "GLACIER", 100, 0, false)
+	if err != nil {
+		t.Fatalf("newObjectFilter: %v", err)
+	}
+
+	if !f.shouldDeleteMarker("app.log") {
+		t.Error("delete markers ignore size/storage-class predicates and should match on suffix alone")
+	}
+	if f.shouldDeleteMarker("app.txt") {
+		t.Error("expected a wrong-suffix marker key to not match")
+	}
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"golang.org/x/time/rate"
+)
+
+// workerConfig bundles the dependencies a delete worker needs beyond the
+// batch it's currently processing: the S3 client, rate/concurrency limits,
+// retry policy, and the sinks for checkpoints and permanently failed keys.
+type workerConfig struct {
+	client *s3.Client
+	bucket string
+
+	checkpoint *checkpointWriter
+	failedKeys *failedKeysWriter
+
+	limiter  *rate.Limiter
+	inflight chan struct{}
+
+	maxRetries              int
+	maxAuthFailures         int32
+	consecutiveAuthFailures *int32
+	batchSeq                *int64
+}
+
+// worker pulls batches of records off objectsToDelete and deletes them,
+// retrying transient per-key failures with exponential backoff and routing
+// permanent failures to cfg.failedKeys. It aborts the whole run if
+// maxAuthFailures consecutive authentication/authorization errors occur.
+func worker(cfg *workerConfig, objectsToDelete <-chan []objectRecord, wg *sync.WaitGroup, totalDeleted *int64) {
+	defer wg.Done()
+
+	for records := range objectsToDelete {
+		batchID := atomic.AddInt64(cfg.batchSeq, 1)
+		start := time.Now()
+		succeeded, failed := deleteBatchWithRetry(cfg, records)
+		duration := time.Since(start)
+
+		var errMsg string
+		if len(failed) > 0 {
+			errMsg = failed[0].message
+		}
+		progressLogger.Info("batch delete",
+			"batch_id", batchID,
+			"keys", len(records),
+			"duration_ms", duration.Milliseconds(),
+			"err", errMsg,
+		)
+
+		if len(succeeded) > 0 {
+			var bytesReclaimed int64
+			for _, rec := range succeeded {
+				bytesReclaimed += rec.Size
+			}
+
+			atomic.AddInt64(totalDeleted, int64(len(succeeded)))
+			recordDeleted(len(succeeded), bytesReclaimed)
+
+			if cfg.checkpoint != nil {
+				if err := cfg.checkpoint.record(succeeded); err != nil {
+					log.Printf("Failed to write checkpoint: %v", err)
+				}
+			}
+		}
+
+		for _, f := range failed {
+			log.Printf("Permanent failure deleting %s: %s: %s", f.record.Key, f.code, f.message)
+			if cfg.failedKeys != nil {
+				if err := cfg.failedKeys.record(f); err != nil {
+					log.Printf("Failed to write failed-keys entry: %v", err)
+				}
+			}
+		}
+	}
+}
+
+// deleteBatchWithRetry issues DeleteObjects for records, retrying keys that
+// come back with a transient error code with exponential backoff and
+// jitter, up to cfg.maxRetries times. It returns the records that were
+// ultimately deleted and the ones that failed permanently.
+func deleteBatchWithRetry(cfg *workerConfig, records []objectRecord) (succeeded []objectRecord, failed []failedKey) {
+	pending := records
+
+	for attempt := 0; len(pending) > 0; attempt++ {
+		if cfg.inflight != nil {
+			cfg.inflight <- struct{}{}
+		}
+		if cfg.limiter != nil {
+			cfg.limiter.Wait(context.TODO())
+		}
+
+		objects := make([]types.ObjectIdentifier, len(pending))
+		for i, rec := range pending {
+			objects[i] = rec.toObjectIdentifier()
+		}
+
+		callStart := time.Now()
+		out, err := cfg.client.DeleteObjects(context.TODO(), &s3.DeleteObjectsInput{
+			Bucket: aws.String(cfg.bucket),
+			Delete: &types.Delete{
+				Objects: objects,
+				Quiet:   aws.Bool(true),
+			},
+		})
+		observeBatchDuration(time.Since(callStart).Seconds())
+
+		if cfg.inflight != nil {
+			<-cfg.inflight
+		}
+
+		if err != nil {
+			code := errorCode(err)
+			recordDeleteError(code)
+			if isAuthError(code) {
+				cfg.reportAuthFailure()
+			}
+
+			// Only transient codes and non-API errors (code "", e.g. a network
+			// failure) are retried; any other code fails the batch at once.
+			if attempt >= cfg.maxRetries || (code != "" && !isTransientCode(code)) {
+				for _, rec := range pending {
+					failed = append(failed, failedKey{record: rec, code: code, message: err.Error()})
+				}
+				return succeeded, failed
+			}
+
+			sleepBackoff(attempt)
+			continue
+		}
+
+		recordsByKey := make(map[string]objectRecord, len(pending))
+		for _, rec := range pending {
+			recordsByKey[rec.checkpointKey()] = rec
+		}
+
+		var retryable []objectRecord
+		failedThisRound := make(map[string]bool, len(out.Errors))
+
+		for _, e := range out.Errors {
+			key := objectRecord{Key: aws.ToString(e.Key), VersionId: aws.ToString(e.VersionId)}.checkpointKey()
+			rec, ok := recordsByKey[key]
+			if !ok {
+				continue
+			}
+			failedThisRound[key] = true
+
+			code := aws.ToString(e.Code)
+			recordDeleteError(code)
+			if isAuthError(code) {
+				cfg.reportAuthFailure()
+			}
+
+			if isTransientCode(code) && attempt < cfg.maxRetries {
+				retryable = append(retryable, rec)
+				continue
+			}
+
+			failed = append(failed, failedKey{record: rec, code: code, message: aws.ToString(e.Message)})
+		}
+
+		for _, rec := range pending {
+			if !failedThisRound[rec.checkpointKey()] {
+				succeeded = append(succeeded, rec)
+				cfg.resetAuthFailures()
+			}
+		}
+
+		if len(retryable) == 0 {
+			return succeeded, failed
+		}
+
+		sleepBackoff(attempt)
+		pending = retryable
+	}
+
+	return succeeded, failed
+}
+
+// reportAuthFailure increments the shared consecutive-auth-failure counter
+// and aborts the whole process once it reaches cfg.maxAuthFailures. Workers
+// share this counter because a credentials problem affects every worker at
+// once, not just the one that happened to observe it first.
+func (cfg *workerConfig) reportAuthFailure() {
+	if cfg.maxAuthFailures <= 0 {
+		return
+	}
+	if atomic.AddInt32(cfg.consecutiveAuthFailures, 1) >= cfg.maxAuthFailures {
+		log.Fatalf("Aborting: %d consecutive authentication/authorization failures", cfg.maxAuthFailures)
+	}
+}
+
+func (cfg *workerConfig) resetAuthFailures() {
+	atomic.StoreInt32(cfg.consecutiveAuthFailures, 0)
+}